@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlertRule is an alerting expression evaluated against the accumulated
+// Stats, independent of the advice-lookup Rules. The syntax is
+// deliberately small and borrows from Prometheus alerting semantics: Match
+// selects which incidents the rule applies to, Condition decides whether
+// it fires, and the optional For window delays firing until the condition
+// has held for that long. The time held is the larger of the wall-clock
+// time since the condition first held on successive evaluations and the
+// incident's own FirstTime..LastTime span, so an incident whose span
+// already covers For fires on its first evaluation, in follow mode too.
+type AlertRule struct {
+	Name      string `json:"name"`
+	Match     string `json:"match"`     // substring match against "<path> <error>", or a glob against Path if it contains "*"
+	Condition string `json:"condition"` // "<metric> <op> <value>", metric one of count|unique_ips|rate_per_min|duration
+	Severity  string `json:"severity"`  // info | warn | critical
+	For       string `json:"for"`       // optional duration, e.g. "2m"; defaults to firing immediately
+}
+
+// FiringAlert is one AlertRule that matched one incident on a given
+// evaluation.
+type FiringAlert struct {
+	Rule      AlertRule
+	Path      string
+	ErrorMsg  string
+	Value     float64
+	Condition string
+}
+
+type alertHold struct {
+	heldSince time.Time
+	delivered bool // whether a webhook has already gone out for this firing streak
+}
+
+// webhookClient bounds how long a single webhook delivery can block.
+// Evaluate runs on the hot path in follow mode (every tick, inside the
+// same select loop that reads the watched file), so a hung endpoint must
+// not be able to stall the rest of the tool indefinitely.
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// AlertEngine evaluates a fixed set of AlertRules against successive
+// Analysis snapshots, tracking how long each (rule, incident) pair has
+// continuously satisfied its condition so "for" windows work across
+// follow-mode ticks.
+type AlertEngine struct {
+	rules      []AlertRule
+	webhookURL string
+	held       map[string]*alertHold
+}
+
+func NewAlertEngine(rules []AlertRule, webhookURL string) *AlertEngine {
+	return &AlertEngine{
+		rules:      rules,
+		webhookURL: webhookURL,
+		held:       make(map[string]*alertHold),
+	}
+}
+
+// Evaluate runs every rule against every incident in an, returning the
+// alerts that are currently firing (condition true for at least the
+// rule's "for" window). The first tick a given (rule, incident) pair
+// fires, it's also delivered to the configured webhook in the
+// background; it is not re-delivered on every later tick the condition
+// keeps holding, only once it has cleared and fires again.
+func (e *AlertEngine) Evaluate(an *Analysis) []FiringAlert {
+	var firing []FiringAlert
+	var toDeliver []FiringAlert
+	now := time.Now()
+
+	for _, rule := range e.rules {
+		forDur, _ := time.ParseDuration(rule.For) // zero value if unset or invalid: fires on first match
+
+		for _, stat := range an.Stats {
+			if !matchesAlertRule(rule.Match, stat) {
+				continue
+			}
+			value, pass, err := evalAlertCondition(rule.Condition, stat)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "alerts: rule %q: %v\n", rule.Name, err)
+				continue
+			}
+
+			key := rule.Name + "|" + stat.Signature
+			if !pass {
+				delete(e.held, key)
+				continue
+			}
+
+			h, ok := e.held[key]
+			if !ok {
+				h = &alertHold{heldSince: now}
+				e.held[key] = h
+			}
+
+			// In follow mode, wall-clock time between ticks is what
+			// grows this; in a single batch evaluation there's only one
+			// tick, so that alone would never satisfy a non-zero "for".
+			// The incident's own FirstTime..LastTime span already shows
+			// how long it has been observed to hold, so take whichever
+			// is larger instead of wall-clock alone.
+			elapsed := now.Sub(h.heldSince)
+			if span := stat.LastTime.Sub(stat.FirstTime); span > elapsed {
+				elapsed = span
+			}
+			if elapsed >= forDur {
+				fa := FiringAlert{
+					Rule:      rule,
+					Path:      stat.Path,
+					ErrorMsg:  stat.ErrorMsg,
+					Value:     value,
+					Condition: rule.Condition,
+				}
+				firing = append(firing, fa)
+				if !h.delivered {
+					h.delivered = true
+					toDeliver = append(toDeliver, fa)
+				}
+			}
+		}
+	}
+
+	if e.webhookURL != "" && len(toDeliver) > 0 {
+		go deliverWebhooks(e.webhookURL, toDeliver)
+	}
+	return firing
+}
+
+// matchesAlertRule mirrors the "fullSig" substring match the advice Rules
+// already use, except a Match containing "*" is instead treated as a glob
+// against the incident's path.
+func matchesAlertRule(match string, stat *Stats) bool {
+	if match == "" {
+		return true
+	}
+	if strings.Contains(match, "*") {
+		ok, err := filepath.Match(match, stat.Path)
+		return err == nil && ok
+	}
+	fullSig := fmt.Sprintf("%s %s", stat.Path, strings.ReplaceAll(stat.ErrorMsg, "\n", " "))
+	return strings.Contains(fullSig, match)
+}
+
+// evalAlertCondition parses and evaluates a "<metric> <op> <value>"
+// condition against stat, returning the computed metric value and whether
+// it satisfies the condition.
+func evalAlertCondition(condition string, stat *Stats) (value float64, pass bool, err error) {
+	fields := strings.Fields(condition)
+	if len(fields) != 3 {
+		return 0, false, fmt.Errorf("malformed condition %q", condition)
+	}
+	metric, op, rawThreshold := fields[0], fields[1], fields[2]
+
+	duration := stat.LastTime.Sub(stat.FirstTime)
+	switch metric {
+	case "count":
+		value = float64(stat.Count)
+	case "unique_ips":
+		value = float64(len(stat.UniqueIPs))
+	case "rate_per_min":
+		mins := duration.Minutes()
+		if mins <= 0 {
+			mins = 1.0 / 60 // a single-second burst still has a rate
+		}
+		value = float64(stat.Count) / mins
+	case "duration":
+		value = duration.Seconds()
+	default:
+		return 0, false, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	var threshold float64
+	if metric == "duration" {
+		d, perr := time.ParseDuration(rawThreshold)
+		if perr != nil {
+			return 0, false, fmt.Errorf("bad duration threshold %q: %w", rawThreshold, perr)
+		}
+		threshold = d.Seconds()
+	} else {
+		threshold, err = strconv.ParseFloat(rawThreshold, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("bad numeric threshold %q: %w", rawThreshold, err)
+		}
+	}
+
+	switch op {
+	case ">":
+		pass = value > threshold
+	case ">=":
+		pass = value >= threshold
+	case "<":
+		pass = value < threshold
+	case "<=":
+		pass = value <= threshold
+	case "==":
+		pass = value == threshold
+	default:
+		return 0, false, fmt.Errorf("unknown operator %q", op)
+	}
+	return value, pass, nil
+}
+
+// printAlerts renders the ALERTS section of the report. The section header
+// is always printed, followed by "(none firing)" when nothing is firing;
+// that output is the same whether no rule fired or no alert rules are
+// configured at all.
+func printAlerts(firing []FiringAlert) {
+	fmt.Println("\nALERTS")
+	fmt.Println(strings.Repeat("=", 80))
+	if len(firing) == 0 {
+		fmt.Println("(none firing)")
+	}
+	for _, a := range firing {
+		severity := a.Rule.Severity
+		if severity == "" {
+			severity = "warn"
+		}
+		fmt.Printf("%-12s [%s] %s\n", "SEVERITY:", strings.ToUpper(severity), a.Rule.Name)
+		fmt.Printf("%-12s %s\n", "PATH:", a.Path)
+		fmt.Printf("%-12s %s\n", "CONDITION:", a.Condition)
+		fmt.Printf("%-12s %.2f\n", "VALUE:", a.Value)
+		fmt.Println(strings.Repeat("-", 80))
+	}
+}
+
+type webhookPayload struct {
+	Rule      string    `json:"rule"`
+	Severity  string    `json:"severity"`
+	Path      string    `json:"path"`
+	Error     string    `json:"error"`
+	Condition string    `json:"condition"`
+	Value     float64   `json:"value"`
+	FiredAt   time.Time `json:"fired_at"`
+}
+
+// deliverWebhooks POSTs one JSON payload per firing alert to url, so the
+// receiving end (Alertmanager, a Slack webhook, PagerDuty's events API)
+// sees one notification per incident rather than a batch it has to
+// unpack itself. It uses webhookClient, which has a bounded timeout, and
+// Evaluate always invokes it in its own goroutine so a slow or hung
+// endpoint can't stall follow mode's tick loop.
+func deliverWebhooks(url string, firing []FiringAlert) {
+	for _, a := range firing {
+		payload := webhookPayload{
+			Rule:      a.Rule.Name,
+			Severity:  a.Rule.Severity,
+			Path:      a.Path,
+			Error:     a.ErrorMsg,
+			Condition: a.Condition,
+			Value:     a.Value,
+			FiredAt:   time.Now(),
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "alerts: marshalling webhook payload: %v\n", err)
+			continue
+		}
+		resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "alerts: webhook delivery failed: %v\n", err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
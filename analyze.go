@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// --- CONFIGURATION ---
+
+type Rule struct {
+	Pattern string `json:"pattern"`
+	Advice  string `json:"advice"`
+}
+
+type VaultAudit struct {
+	Time    string `json:"time"`
+	Error   string `json:"error"`
+	Request struct {
+		Operation     string `json:"operation"`
+		Path          string `json:"path"`
+		RemoteAddress string `json:"remote_address"`
+		Namespace     struct {
+			Path string `json:"path"`
+		} `json:"namespace"`
+	} `json:"request"`
+}
+
+type Stats struct {
+	Signature string          `json:"signature"`
+	Path      string          `json:"path"`
+	ErrorMsg  string          `json:"error"`
+	Namespace string          `json:"namespace,omitempty"`
+	Count     int             `json:"count"`
+	FirstTime time.Time       `json:"first_time"`
+	LastTime  time.Time       `json:"last_time"`
+	UniqueIPs map[string]bool `json:"-"`
+}
+
+// statsJSON is the JSON wire shape of Stats: UniqueIPs marshals as a
+// sorted slice of addresses rather than the map Stats uses internally for
+// O(1) membership checks while accumulating.
+type statsJSON struct {
+	Signature string    `json:"signature"`
+	Path      string    `json:"path"`
+	ErrorMsg  string    `json:"error"`
+	Namespace string    `json:"namespace,omitempty"`
+	Count     int       `json:"count"`
+	FirstTime time.Time `json:"first_time"`
+	LastTime  time.Time `json:"last_time"`
+	UniqueIPs []string  `json:"unique_ips"`
+}
+
+func (s *Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statsJSON{
+		Signature: s.Signature,
+		Path:      s.Path,
+		ErrorMsg:  s.ErrorMsg,
+		Namespace: s.Namespace,
+		Count:     s.Count,
+		FirstTime: s.FirstTime,
+		LastTime:  s.LastTime,
+		UniqueIPs: mapToSortedSlice(s.UniqueIPs),
+	})
+}
+
+// Analysis is the accumulated state produced by analyze(). It is shared
+// between single-shot runs and follow mode, which keeps mutating the same
+// Analysis across successive reads of the same file.
+type Analysis struct {
+	Stats         map[string]*Stats
+	PathStats     map[string]int
+	RawErrorStats map[string]int
+}
+
+func newAnalysis() *Analysis {
+	return &Analysis{
+		Stats:         make(map[string]*Stats),
+		PathStats:     make(map[string]int),
+		RawErrorStats: make(map[string]int),
+	}
+}
+
+// AnalyzeOptions bundles the knobs that affect how analyze() folds an
+// entry into an Analysis, beyond the raw scan itself.
+type AnalyzeOptions struct {
+	// Anomalies, if non-nil, receives every event for time-bucketed
+	// spike detection.
+	Anomalies *AnomalyDetector
+	// Since and Until bound the parsed entries to an incident window;
+	// the zero value leaves that side unbounded.
+	Since time.Time
+	Until time.Time
+}
+
+// analyze scans r line-by-line for Vault audit entries and folds them into
+// an. It can be called repeatedly against the same an with a reader that
+// only contains the newly appended bytes, which is what follow mode does.
+func analyze(r *bufio.Scanner, an *Analysis, opts AnalyzeOptions) {
+	for r.Scan() {
+		line := r.Text()
+		start := strings.Index(line, "{")
+		if start == -1 {
+			continue
+		}
+
+		var entry VaultAudit
+		if err := json.Unmarshal([]byte(line[start:]), &entry); err != nil {
+			continue
+		}
+		if entry.Error == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil {
+			// Missing/unparseable time: there's nothing sane to bucket
+			// or bound this entry against, and a zero time would look
+			// like an event from the distant past to every downstream
+			// consumer (FirstTime, anomaly buckets, alert windows).
+			continue
+		}
+		if !opts.Since.IsZero() && t.Before(opts.Since) {
+			continue
+		}
+		if !opts.Until.IsZero() && t.After(opts.Until) {
+			continue
+		}
+
+		// --- NO NORMALIZATION (Raw is Truth) ---
+		rawErr := entry.Error
+
+		an.PathStats[entry.Request.Path]++
+		an.RawErrorStats[rawErr]++
+
+		cleanKey := strings.TrimSpace(rawErr)
+		sig := fmt.Sprintf("%s|%s", entry.Request.Path, cleanKey)
+
+		if _, exists := an.Stats[sig]; !exists {
+			an.Stats[sig] = &Stats{
+				Signature: sig,
+				Path:      entry.Request.Path,
+				ErrorMsg:  rawErr,
+				Namespace: entry.Request.Namespace.Path,
+				FirstTime: t,
+				LastTime:  t,
+				UniqueIPs: make(map[string]bool),
+			}
+		}
+
+		stat := an.Stats[sig]
+		stat.Count++
+		if entry.Request.Namespace.Path != "" {
+			stat.Namespace = entry.Request.Namespace.Path
+		}
+		if t.Before(stat.FirstTime) {
+			stat.FirstTime = t
+		}
+		if t.After(stat.LastTime) {
+			stat.LastTime = t
+		}
+		if entry.Request.RemoteAddress != "" {
+			stat.UniqueIPs[entry.Request.RemoteAddress] = true
+		}
+
+		if opts.Anomalies != nil {
+			opts.Anomalies.Observe(sig, t)
+		}
+	}
+}
+
+func newScanner(r interface {
+	Read(p []byte) (n int, err error)
+}) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 1024*1024)
+	scanner.Buffer(buf, 1024*1024)
+	return scanner
+}
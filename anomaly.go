@@ -0,0 +1,181 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AnomalyConfig controls the time-bucketed spike detector.
+type AnomalyConfig struct {
+	BucketWidth time.Duration
+	Alpha       float64 // EWMA smoothing factor
+	Warmup      int     // buckets required before a signature's baseline is trusted
+	Z           float64 // stddev multiplier above the EWMA mean that counts as a spike
+}
+
+// DefaultAnomalyConfig matches the detector's documented defaults: 1
+// minute buckets, a 5-bucket warmup, and a 3-sigma threshold.
+func DefaultAnomalyConfig() AnomalyConfig {
+	return AnomalyConfig{
+		BucketWidth: time.Minute,
+		Alpha:       0.3,
+		Warmup:      5,
+		Z:           3.0,
+	}
+}
+
+// Spike is one time bucket whose event count blew past its signature's
+// EWMA baseline.
+type Spike struct {
+	Bucket   time.Time `json:"bucket" yaml:"bucket"`
+	Observed int       `json:"observed" yaml:"observed"`
+	Expected float64   `json:"expected" yaml:"expected"`
+	StdDev   float64   `json:"stddev" yaml:"stddev"`
+}
+
+// sigBaseline is the running EWMA mean/variance and in-progress bucket for
+// one error signature.
+type sigBaseline struct {
+	bucketStart time.Time
+	count       int
+	bucketsSeen int
+	mean        float64
+	variance    float64
+}
+
+// AnomalyDetector bins each error signature's events into fixed-width time
+// buckets and maintains an EWMA mean/variance of the per-bucket counts
+// (Welford-style online update: mean += alpha*(x-mean), variance decayed by
+// the same factor), flagging any bucket whose count exceeds
+// mean + z*stddev once the baseline has warmed up. Events are assumed to
+// arrive in roughly chronological order, which holds for an append-only
+// audit log; a bucket that arrives out of order just folds into whichever
+// bucket is currently open for that signature.
+type AnomalyDetector struct {
+	cfg       AnomalyConfig
+	baselines map[string]*sigBaseline
+	spikes    map[string][]Spike
+}
+
+func NewAnomalyDetector(cfg AnomalyConfig) *AnomalyDetector {
+	return &AnomalyDetector{
+		cfg:       cfg,
+		baselines: make(map[string]*sigBaseline),
+		spikes:    make(map[string][]Spike),
+	}
+}
+
+// maxGapBuckets bounds how many empty buckets Observe will walk forward
+// through to fill a gap. Anything wider than this is treated as a reset
+// rather than filled one bucket at a time - both to keep a single
+// corrupt or far-future timestamp from iterating effectively forever,
+// and because a gap that large has nothing meaningful left to baseline
+// against anyway.
+const maxGapBuckets = 10000
+
+// Observe folds one event into sig's current bucket, closing out and
+// scoring the previous bucket first if t has moved into a new one. A gap
+// of inactivity between the two is scored as a run of zero-count buckets
+// rather than skipped, so the baseline reflects quiet periods too and a
+// burst after silence still stands out against them. t must not be the
+// zero time - callers are expected to have already dropped entries whose
+// timestamp failed to parse.
+func (d *AnomalyDetector) Observe(sig string, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	bucketStart := t.Truncate(d.cfg.BucketWidth)
+
+	b, ok := d.baselines[sig]
+	if !ok {
+		d.baselines[sig] = &sigBaseline{bucketStart: bucketStart, count: 1}
+		return
+	}
+
+	if bucketStart.Sub(b.bucketStart) > time.Duration(maxGapBuckets)*d.cfg.BucketWidth {
+		// Implausibly large gap (e.g. a corrupt or far-future
+		// timestamp slipping past upstream validation) - restart the
+		// baseline at the new bucket instead of filling millions of
+		// zero buckets one at a time.
+		d.baselines[sig] = &sigBaseline{bucketStart: bucketStart, count: 1}
+		return
+	}
+
+	for bucketStart.After(b.bucketStart) {
+		d.closeBucket(sig, b)
+		b.bucketStart = b.bucketStart.Add(d.cfg.BucketWidth)
+		b.count = 0
+	}
+	b.count++
+}
+
+// Finalize closes out every signature's last in-progress bucket, so the
+// most recent bucket is scored too rather than silently dropped. It must
+// only be called once a stream of Observe calls is truly done (end of a
+// single-shot run, or end of one file during parallel ingestion) - unlike
+// Observe, it scores the open bucket unconditionally, so calling it again
+// before a new bucketStart has opened scores that same bucket a second
+// time, folding an extra bucket (holding only the events observed since
+// the last call) into the baseline. Follow mode must not call this on every render tick;
+// it should only read Spikes/TotalSpikes, which reflect whatever buckets
+// Observe has already closed on its own.
+func (d *AnomalyDetector) Finalize() {
+	for sig, b := range d.baselines {
+		d.closeBucket(sig, b)
+		// Re-open the same bucket so a later Observe for it doesn't
+		// double-count; only a new bucketStart should trigger another close.
+		b.count = 0
+	}
+}
+
+// Merge folds another detector's already-scored spikes into d. Used by
+// parallel ingestion, where each file is scored against its own detector
+// (EWMA needs chronological order, which only holds within one file) and
+// the results are combined afterward; baselines themselves are not merged
+// since they're meaningless once their source file is done.
+func (d *AnomalyDetector) Merge(other *AnomalyDetector) {
+	for sig, spikes := range other.spikes {
+		merged := append(d.spikes[sig], spikes...)
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].Bucket.Before(merged[j].Bucket)
+		})
+		d.spikes[sig] = merged
+	}
+}
+
+func (d *AnomalyDetector) closeBucket(sig string, b *sigBaseline) {
+	x := float64(b.count)
+	b.bucketsSeen++
+
+	if b.bucketsSeen > d.cfg.Warmup {
+		stddev := math.Sqrt(b.variance)
+		if x > b.mean+d.cfg.Z*stddev {
+			d.spikes[sig] = append(d.spikes[sig], Spike{
+				Bucket:   b.bucketStart,
+				Observed: b.count,
+				Expected: b.mean,
+				StdDev:   stddev,
+			})
+		}
+	}
+
+	diff := x - b.mean
+	incr := d.cfg.Alpha * diff
+	b.mean += incr
+	b.variance = (1 - d.cfg.Alpha) * (b.variance + diff*incr)
+}
+
+// Spikes returns the spikes detected so far for sig, in chronological order.
+func (d *AnomalyDetector) Spikes(sig string) []Spike {
+	return d.spikes[sig]
+}
+
+// TotalSpikes returns the number of spikes detected across all signatures.
+func (d *AnomalyDetector) TotalSpikes() int {
+	n := 0
+	for _, s := range d.spikes {
+		n += len(s)
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnomalyDetectorObserveIgnoresZeroTime(t *testing.T) {
+	d := NewAnomalyDetector(DefaultAnomalyConfig())
+	d.Observe("sig", time.Time{})
+
+	if len(d.baselines) != 0 {
+		t.Fatalf("expected a zero-time event to be ignored, got baseline %+v", d.baselines["sig"])
+	}
+}
+
+// TestAnomalyDetectorObserveCapsLargeGap guards against the bug fixed
+// alongside this test: a signature's first event having an unparseable
+// (zero-value) timestamp followed by a normal one, or any other
+// implausibly large gap, used to make Observe walk forward one
+// BucketWidth at a time - millions of iterations - instead of returning.
+func TestAnomalyDetectorObserveCapsLargeGap(t *testing.T) {
+	d := NewAnomalyDetector(DefaultAnomalyConfig())
+	// One minute past the zero time: Observe drops zero-time events
+	// outright, so the zero time itself would never reach the gap check.
+	start := time.Date(1, 1, 1, 0, 1, 0, 0, time.UTC)
+	far := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	done := make(chan struct{})
+	go func() {
+		d.Observe("sig", start)
+		d.Observe("sig", far)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Observe did not return within 2s for a multi-millennium gap; the zero-bucket fill loop is unbounded")
+	}
+
+	b := d.baselines["sig"]
+	if b == nil {
+		t.Fatal("expected a baseline for sig after observing the second event")
+	}
+	if !b.bucketStart.Equal(far.Truncate(d.cfg.BucketWidth)) {
+		t.Fatalf("expected the baseline to reset to the new event's bucket, got %v", b.bucketStart)
+	}
+}
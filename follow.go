@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/fsnotify.v1"
+
+	"vault-analyzer-audit/internal/metrics"
+)
+
+// runFollow tails targetFile like `tail -f`, re-running analyze() against
+// newly appended bytes and re-emitting the report every interval. It
+// watches the containing directory (rather than the file descriptor
+// directly) so it notices logrotate/journald-style rotation: the old file
+// is renamed or removed and a new file is created in its place. If reg is
+// non-nil, it is updated with the latest snapshot on every tick as well.
+// alerts is evaluated against the same snapshot every tick, printing an
+// ALERTS section and delivering any configured webhook. format selects the
+// Reporter rebuilt for each tick's render (a fresh one per tick since most
+// non-text formats buffer a whole document rather than streaming).
+// anomalies accumulates time-bucketed spike detection across ticks.
+func runFollow(targetFile string, interval time.Duration, rules []Rule, reg *metrics.Registry, alerts *AlertEngine, anomalies *AnomalyDetector, format string) error {
+	dir := filepath.Dir(targetFile)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("follow: creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("follow: watching %s: %w", dir, err)
+	}
+
+	an := newAnalysis()
+
+	f, offset, err := openAtEnd(targetFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// pending holds bytes read past the last complete, newline-terminated
+	// line - a tick can land mid-write, and a bufio.Scanner happily hands
+	// back that trailing partial line as its last token at EOF. Holding
+	// it back (instead of letting it fail JSON parsing and advancing
+	// offset past it anyway) means the record isn't silently dropped once
+	// the rest of the line is written.
+	var pending []byte
+
+	readNew := func() {
+		if f == nil {
+			return
+		}
+		info, err := f.Stat()
+		if err != nil {
+			return
+		}
+		if info.Size() < offset {
+			// File shrank in place without a rename/create event
+			// (e.g. truncated by the writer) - treat as rotation.
+			offset = 0
+			pending = nil
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return
+		}
+		chunk, err := io.ReadAll(f)
+		if err != nil || len(chunk) == 0 {
+			return
+		}
+		offset += int64(len(chunk))
+
+		data := append(pending, chunk...)
+		lastNL := bytes.LastIndexByte(data, '\n')
+		if lastNL == -1 {
+			// No complete line yet at all; hold everything back rather
+			// than handing a guaranteed-partial line to analyze().
+			pending = data
+			return
+		}
+
+		complete := data[:lastNL+1]
+		pending = append([]byte(nil), data[lastNL+1:]...)
+		analyze(newScanner(bytes.NewReader(complete)), an, AnalyzeOptions{Anomalies: anomalies})
+	}
+
+	reopen := func() {
+		if f != nil {
+			f.Close()
+			f = nil
+		}
+		newFile, newOffset, err := openAtEnd(targetFile)
+		if err != nil {
+			// The file may not have reappeared yet (rename happens
+			// before the new file is created); retry on the next tick.
+			return
+		}
+		f = newFile
+		offset = newOffset
+		pending = nil
+	}
+
+	render := func() {
+		fmt.Print("\033[H\033[2J") // clear screen between ticks in follow mode
+		rep, err := newReporter(format, os.Stdout)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		// Don't Finalize() here: that scores a signature's open bucket
+		// unconditionally, and calling it every tick would score the
+		// same open bucket again each time, folding an extra bucket into
+		// the baseline per tick. Observe
+		// already closes and scores a bucket as soon as a newer one
+		// opens, so the report below reflects whatever is complete so far.
+		if err := writeReport(an, rules, rep, anomalies); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		printAlerts(alerts.Evaluate(an))
+		if reg != nil {
+			reg.Update(toMetricsView(an))
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(targetFile) {
+				continue
+			}
+			switch {
+			case event.Op&fsnotify.Write == fsnotify.Write:
+				readNew()
+			case event.Op&(fsnotify.Rename|fsnotify.Remove) != 0:
+				reopen()
+			case event.Op&fsnotify.Create == fsnotify.Create:
+				reopen()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "follow: watcher error: %v\n", err)
+		case <-ticker.C:
+			if f == nil {
+				reopen()
+			}
+			// Pick up anything the Write events may have missed and
+			// re-emit the incident/summary report on the configured cadence.
+			readNew()
+			render()
+		}
+	}
+}
+
+// openAtEnd opens path and seeks to its current end, so following starts
+// from "now" rather than replaying the whole history of the file.
+func openAtEnd(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("follow: opening %s: %w", path, err)
+	}
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("follow: seeking %s: %w", path, err)
+	}
+	return f, offset, nil
+}
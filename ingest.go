@@ -0,0 +1,233 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// logExtensions are the files a directory argument is walked for; a
+// literal file or glob argument is taken as-is regardless of extension.
+var logExtensions = []string{".log", ".json", ".log.gz", ".log.zst"}
+
+// expandTargets turns the CLI's positional arguments - files, glob
+// patterns, and directories - into a flat list of concrete file paths.
+// Directories are walked recursively, matching only logExtensions.
+func expandTargets(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, statErr := os.Stat(arg)
+		switch {
+		case statErr == nil && info.IsDir():
+			walked, err := walkLogDir(arg)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, walked...)
+		case statErr == nil:
+			files = append(files, arg)
+		case strings.ContainsAny(arg, "*?["):
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("bad glob %q: %w", arg, err)
+			}
+			files = append(files, matches...)
+		default:
+			return nil, fmt.Errorf("%s: %w", arg, statErr)
+		}
+	}
+	return files, nil
+}
+
+func walkLogDir(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		for _, ext := range logExtensions {
+			if strings.HasSuffix(path, ext) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// openTarget opens path, transparently wrapping it in a gzip or zstd
+// decompressor based on its extension so callers can always just Read it.
+func openTarget(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("opening gzip %s: %w", path, err)
+		}
+		return &gzipReadCloser{gz: gz, f: f}, nil
+	case strings.HasSuffix(path, ".zst"):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("opening zstd %s: %w", path, err)
+		}
+		return &zstdReadCloser{zr: zr, f: f}, nil
+	default:
+		return f, nil
+	}
+}
+
+type gzipReadCloser struct {
+	gz *gzip.Reader
+	f  *os.File
+}
+
+func (g *gzipReadCloser) Read(p []byte) (int, error) { return g.gz.Read(p) }
+func (g *gzipReadCloser) Close() error {
+	g.gz.Close()
+	return g.f.Close()
+}
+
+type zstdReadCloser struct {
+	zr *zstd.Decoder
+	f  *os.File
+}
+
+func (z *zstdReadCloser) Read(p []byte) (int, error) { return z.zr.Read(p) }
+func (z *zstdReadCloser) Close() error {
+	z.zr.Close()
+	return z.f.Close()
+}
+
+// ingestResult is one worker's fully-parsed local Analysis for a single
+// file, handed off to the aggregator goroutine.
+type ingestResult struct {
+	path      string
+	an        *Analysis
+	anomalies *AnomalyDetector
+	err       error
+}
+
+// ingestAll scans every file in paths using a pool of workers goroutines
+// (runtime.NumCPU() if workers <= 0), each accumulating into its own local
+// Analysis so the workers never contend on a shared lock; a dedicated
+// aggregator goroutine merges each worker's result as it arrives on the
+// results channel into a single unified Analysis.
+//
+// opts.Anomalies, if set, is never shared across workers: AnomalyDetector
+// keeps no internal locking, and its EWMA baseline depends on events
+// arriving in chronological order, which only holds within a single file.
+// Each worker instead scores its file against its own detector seeded
+// from the same config, and the results are merged into opts.Anomalies
+// once the file is done.
+func ingestAll(paths []string, workers int, opts AnalyzeOptions) (*Analysis, []error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan string)
+	results := make(chan ingestResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				local := newAnalysis()
+				localOpts := opts
+				var localAnomalies *AnomalyDetector
+				if opts.Anomalies != nil {
+					localAnomalies = NewAnomalyDetector(opts.Anomalies.cfg)
+					localOpts.Anomalies = localAnomalies
+				}
+
+				r, err := openTarget(path)
+				if err != nil {
+					results <- ingestResult{path: path, err: err}
+					continue
+				}
+				analyze(newScanner(r), local, localOpts)
+				r.Close()
+				if localAnomalies != nil {
+					localAnomalies.Finalize()
+				}
+				results <- ingestResult{path: path, an: local, anomalies: localAnomalies}
+			}
+		}()
+	}
+
+	go func() {
+		for _, p := range paths {
+			jobs <- p
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	merged := newAnalysis()
+	var errs []error
+	for res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.path, res.err))
+			continue
+		}
+		mergeAnalysis(merged, res.an)
+		if res.anomalies != nil {
+			opts.Anomalies.Merge(res.anomalies)
+		}
+	}
+	return merged, errs
+}
+
+// mergeAnalysis folds src into dst, summing counts and unioning unique IPs
+// for any signature both share.
+func mergeAnalysis(dst, src *Analysis) {
+	for k, v := range src.PathStats {
+		dst.PathStats[k] += v
+	}
+	for k, v := range src.RawErrorStats {
+		dst.RawErrorStats[k] += v
+	}
+	for sig, s := range src.Stats {
+		existing, ok := dst.Stats[sig]
+		if !ok {
+			dst.Stats[sig] = s
+			continue
+		}
+		existing.Count += s.Count
+		if s.FirstTime.Before(existing.FirstTime) {
+			existing.FirstTime = s.FirstTime
+		}
+		if s.LastTime.After(existing.LastTime) {
+			existing.LastTime = s.LastTime
+		}
+		if s.Namespace != "" {
+			existing.Namespace = s.Namespace
+		}
+		for ip := range s.UniqueIPs {
+			existing.UniqueIPs[ip] = true
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Package metrics exposes the audit analysis accumulated by the main
+// package as Prometheus metrics. Registry holds the series the /metrics
+// HTTP handler serves. The report printers read the main package's
+// Analysis directly, not Registry; Registry is updated from that Analysis
+// once per run, or on every follow-mode tick.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// AnalysisView is the subset of the accumulated audit stats a Registry
+// needs to build metrics from. It mirrors main.Analysis without importing
+// package main, which would create an import cycle.
+type AnalysisView struct {
+	Stats         []StatView
+	PathStats     map[string]int
+	RawErrorStats map[string]int
+}
+
+// StatView mirrors a single main.Stats entry.
+type StatView struct {
+	Path      string
+	ErrorMsg  string
+	Namespace string
+	Count     int
+	FirstTime time.Time
+	LastTime  time.Time
+	UniqueIPs int
+}
+
+// Registry accumulates Prometheus series from successive AnalysisView
+// snapshots and implements prometheus.Collector so it can be registered
+// directly with a prometheus.Registerer.
+type Registry struct {
+	mu sync.Mutex
+
+	maxLabelCardinality int
+	seenLabels          map[string]struct{}
+	lastCount           map[string]int
+
+	errorsTotal  *prometheus.GaugeVec
+	uniqueIPs    *prometheus.GaugeVec
+	interarrival *prometheus.HistogramVec
+}
+
+// NewRegistry builds an empty Registry. maxLabelCardinality bounds how
+// many distinct path/error label values each metric family will track
+// before collapsing further values into "other" to avoid label explosion;
+// 0 disables the cap.
+func NewRegistry(maxLabelCardinality int) *Registry {
+	return &Registry{
+		maxLabelCardinality: maxLabelCardinality,
+		seenLabels:          make(map[string]struct{}),
+		lastCount:           make(map[string]int),
+		errorsTotal: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vault_audit_errors_total",
+			Help: "Count of Vault audit log errors observed, by path, error message and namespace.",
+		}, []string{"path", "error", "namespace"}),
+		uniqueIPs: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "vault_audit_unique_source_ips",
+			Help: "Number of distinct source IPs seen for a given path/error signature.",
+		}, []string{"path", "error"}),
+		interarrival: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vault_audit_error_interarrival_seconds",
+			Help:    "Average time between occurrences of the same path/error signature, computed as (last_time-first_time)/(count-1) over the accumulated window.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"path", "error"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (r *Registry) Describe(ch chan<- *prometheus.Desc) {
+	r.errorsTotal.Describe(ch)
+	r.uniqueIPs.Describe(ch)
+	r.interarrival.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	r.errorsTotal.Collect(ch)
+	r.uniqueIPs.Collect(ch)
+	r.interarrival.Collect(ch)
+}
+
+// Update folds a fresh AnalysisView snapshot into the registry's series.
+// It is safe to call repeatedly, e.g. once per follow-mode tick.
+func (r *Registry) Update(view AnalysisView) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range view.Stats {
+		path := r.capLabel(s.Path)
+		errLabel := r.capLabel(s.ErrorMsg)
+
+		r.errorsTotal.WithLabelValues(path, errLabel, s.Namespace).Set(float64(s.Count))
+		r.uniqueIPs.WithLabelValues(path, errLabel).Set(float64(s.UniqueIPs))
+
+		// Stats only tracks first/last/count, not every individual event
+		// time, so this is the average interarrival over the signature's
+		// whole observed window rather than a true per-event distribution.
+		// That still works in single-shot mode (one Update call) unlike
+		// measuring gaps between snapshots, which was always empty there.
+		sig := s.Path + "|" + s.ErrorMsg
+		if s.Count > 1 && s.Count != r.lastCount[sig] {
+			avg := s.LastTime.Sub(s.FirstTime).Seconds() / float64(s.Count-1)
+			r.interarrival.WithLabelValues(path, errLabel).Observe(avg)
+		}
+		r.lastCount[sig] = s.Count
+	}
+}
+
+// capLabel bounds label cardinality: once maxLabelCardinality distinct
+// values have been seen across all capped labels, further unseen values
+// collapse into "other" rather than minting a new time series.
+func (r *Registry) capLabel(v string) string {
+	if r.maxLabelCardinality <= 0 {
+		return v
+	}
+	if _, ok := r.seenLabels[v]; ok {
+		return v
+	}
+	if len(r.seenLabels) >= r.maxLabelCardinality {
+		return "other"
+	}
+	r.seenLabels[v] = struct{}{}
+	return v
+}
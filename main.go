@@ -1,173 +1,121 @@
 package main
 
 import (
-	"bufio"
 	"embed"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
+
+	"vault-analyzer-audit/internal/metrics"
 )
 
 //go:embed rules.json
 var defaultRulesFS embed.FS
 
-// --- CONFIGURATION ---
-
-type Rule struct {
-	Pattern string `json:"pattern"`
-	Advice  string `json:"advice"`
-}
-
-type VaultAudit struct {
-	Time    string `json:"time"`
-	Error   string `json:"error"`
-	Request struct {
-		Operation     string `json:"operation"`
-		Path          string `json:"path"`
-		RemoteAddress string `json:"remote_address"`
-		Namespace     struct {
-			Path string `json:"path"`
-		} `json:"namespace"`
-	} `json:"request"`
-}
-
-type Stats struct {
-	Signature string
-	Path      string
-	ErrorMsg  string
-	Count     int
-	FirstTime time.Time
-	LastTime  time.Time
-	UniqueIPs map[string]bool
-}
-
 // --- MAIN EXECUTION ---
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: ./vault-analyzer <filename>")
+	follow := flag.Bool("follow", false, "tail the file and keep updating the report as new entries arrive")
+	flag.BoolVar(follow, "f", false, "shorthand for -follow")
+	interval := flag.Duration("interval", 5*time.Second, "how often to re-emit the report while following")
+	metricsAddr := flag.String("metrics-addr", "", "if set, serve Prometheus metrics on this address (e.g. :9090)")
+	metricsLabels := flag.Int("metrics-labels", 200, "max distinct path/error label values per metric before collapsing into \"other\" (0 disables the cap)")
+	alertWebhook := flag.String("alert-webhook", "", "if set, POST a JSON payload to this URL for every firing alert")
+	format := flag.String("format", "text", "report format: text, json, ndjson, yaml, csv, or sarif")
+	bucketWidth := flag.Duration("bucket", time.Minute, "time bucket width for spike detection")
+	spikeZ := flag.Float64("spike-zscore", 3.0, "stddevs above a signature's EWMA baseline that counts as a spike")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of files to ingest concurrently (multi-file/directory mode only)")
+	since := flag.String("since", "", "only consider entries at or after this RFC3339 timestamp (not applied with -follow)")
+	until := flag.String("until", "", "only consider entries at or before this RFC3339 timestamp (not applied with -follow)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: ./vault-analyzer [-follow] [-interval 5s] [-metrics-addr :9090] [-alert-webhook url] [-format text] [-bucket 1m] [-workers N] [-since ts] [-until ts] <file|glob|dir>...")
 		os.Exit(1)
 	}
 
-	targetFile := os.Args[1]
-	
-	// --- HYBRID RULE LOADING ---
-	rules := loadRules()
-
-	file, err := os.Open(targetFile)
-	if err != nil {
-		fmt.Printf("Error: Could not open file '%s'\n", targetFile)
-		return
+	opts := AnalyzeOptions{}
+	if *since != "" {
+		t, err := time.Parse(time.RFC3339, *since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -since: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Since = t
 	}
-	defer file.Close()
-
-	// 2. PARSING & ACCUMULATION
-	analysis := make(map[string]*Stats) 
-	pathStats := make(map[string]int)
-	rawErrorStats := make(map[string]int)
-
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		start := strings.Index(line, "{")
-		if start == -1 { continue }
-
-		var entry VaultAudit
-		if err := json.Unmarshal([]byte(line[start:]), &entry); err != nil { continue }
-		if entry.Error == "" { continue }
-
-		// --- NO NORMALIZATION (Raw is Truth) ---
-		rawErr := entry.Error
-
-		// Collect Stats
-		pathStats[entry.Request.Path]++
-		rawErrorStats[rawErr]++
-
-		cleanKey := strings.TrimSpace(rawErr)
-		sig := fmt.Sprintf("%s|%s", entry.Request.Path, cleanKey)
-		t, _ := time.Parse(time.RFC3339, entry.Time)
-
-		if _, exists := analysis[sig]; !exists {
-			analysis[sig] = &Stats{
-				Signature: sig,
-				Path:      entry.Request.Path,
-				ErrorMsg:  rawErr, 
-				FirstTime: t,
-				LastTime:  t,
-				UniqueIPs: make(map[string]bool),
-			}
+	if *until != "" {
+		t, err := time.Parse(time.RFC3339, *until)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -until: %v\n", err)
+			os.Exit(1)
 		}
+		opts.Until = t
+	}
+
+	// --- HYBRID RULE LOADING ---
+	rules, alertRules := loadConfig()
+	alerts := NewAlertEngine(alertRules, *alertWebhook)
+
+	anomalyCfg := DefaultAnomalyConfig()
+	anomalyCfg.BucketWidth = *bucketWidth
+	anomalyCfg.Z = *spikeZ
+	anomalies := NewAnomalyDetector(anomalyCfg)
+	opts.Anomalies = anomalies
+
+	var reg *metrics.Registry
+	if *metricsAddr != "" {
+		reg = metrics.NewRegistry(*metricsLabels)
+		startMetricsServer(*metricsAddr, reg)
+	}
 
-		stat := analysis[sig]
-		stat.Count++
-		if t.Before(stat.FirstTime) { stat.FirstTime = t }
-		if t.After(stat.LastTime) { stat.LastTime = t }
-		if entry.Request.RemoteAddress != "" {
-			stat.UniqueIPs[entry.Request.RemoteAddress] = true
+	if *follow {
+		// Follow mode tails a single live file; multi-file/directory
+		// ingestion below is for batch analysis of files at rest.
+		if err := runFollow(flag.Arg(0), *interval, rules, reg, alerts, anomalies, *format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
 
-	// 3. SORTING
-	var sorted []*Stats
-	for _, s := range analysis {
-		sorted = append(sorted, s)
+	rep, err := newReporter(*format, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	sort.Slice(sorted, func(i, j int) bool {
-		return sorted[i].Count > sorted[j].Count
-	})
 
-	// 4. PRINTING REPORT
-	fmt.Println("VAULT AUDIT ANALYSIS REPORT")
-	fmt.Println(strings.Repeat("=", 80))
-	
-	for _, inc := range sorted {
-		category := "DATA"
-		if strings.HasPrefix(inc.Path, "sys/") {
-			category = "SYS"
-		} else if strings.HasPrefix(inc.Path, "auth/") {
-			category = "AUTH"
-		}
+	targets, err := expandTargets(flag.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "no matching files to analyze")
+		os.Exit(1)
+	}
 
-		advice := ""
-		flatErr := strings.ReplaceAll(inc.ErrorMsg, "\n", " ")
-		fullSig := fmt.Sprintf("%s %s", inc.Path, flatErr)
-		
-		for _, r := range rules {
-			if strings.Contains(fullSig, r.Pattern) {
-				advice = r.Advice
-				break
-			}
-		}
-		if advice == "" {
-			advice = "Investigate this error pattern."
-		}
+	an, ingestErrs := ingestAll(targets, *workers, opts)
+	for _, e := range ingestErrs {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	anomalies.Finalize()
 
-		duration := inc.LastTime.Sub(inc.FirstTime)
-		
-		fmt.Printf("%-12s [%s]\n", "CATEGORY:", category)
-		fmt.Printf("%-12s %d\n", "COUNT:", inc.Count)
-		fmt.Printf("%-12s %s\n", "PATH:", inc.Path)
-		fmt.Printf("%-12s %s\n", "ERROR:", cleanForDisplay(inc.ErrorMsg))
-		
-		fmt.Printf("%-12s %s -> %s (%s)\n", 
-			"TIMEFRAME:",
-			inc.FirstTime.Format("15:04:05"), 
-			inc.LastTime.Format("15:04:05"), 
-			duration)
-			
-		fmt.Printf("%-12s %v\n", "SOURCES:", mapToSortedSlice(inc.UniqueIPs))
-		fmt.Printf("%-12s %s\n", "ANALYSIS:", advice)
-		fmt.Println(strings.Repeat("-", 80))
+	if err := writeReport(an, rules, rep, anomalies); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+	printAlerts(alerts.Evaluate(an))
 
-	// 5. SUMMARY 
-	printSummary(pathStats, rawErrorStats)
+	if reg != nil {
+		reg.Update(toMetricsView(an))
+		fmt.Println("Metrics server still running; press Ctrl+C to exit.")
+		select {}
+	}
 }
 
 // --- HELPER FUNCTIONS ---
@@ -179,7 +127,11 @@ func cleanForDisplay(s string) string {
 	return s
 }
 
-func loadRules() []Rule {
+// loadConfig loads both the advice Rules and the alerting AlertRules from
+// rules.json. The file can be either the original flat array of advice
+// Rules (kept for backward compatibility) or an object with "rules" and
+// "alerts" keys.
+func loadConfig() ([]Rule, []AlertRule) {
 	var ruleData []byte
 	var err error
 
@@ -191,71 +143,29 @@ func loadRules() []Rule {
 		// 2. Fallback to Embedded Binary
 		ruleData, _ = defaultRulesFS.ReadFile("rules.json")
 	}
-
-	var r []Rule
-	if len(ruleData) > 0 {
-		json.Unmarshal(ruleData, &r)
+	if len(ruleData) == 0 {
+		return nil, nil
 	}
-	return r
-}
 
-func printSummary(pathStats map[string]int, errorStats map[string]int) {
-	fmt.Println("\nEXECUTIVE SUMMARY")
-	fmt.Println(strings.Repeat("=", 80))
-
-	fmt.Println("TOP FAILING PATHS (JSON):")
-	printJSONStats(pathStats, "Path", 3)
-	fmt.Println("")
-
-	fmt.Println("TOP ERROR TYPES (JSON):")
-	printRawErrorJSON(errorStats, 5) 
-	fmt.Println(strings.Repeat("=", 80))
-}
-
-func printJSONStats(stats map[string]int, keyName string, n int) {
-	type kv struct {
-		Key   string
-		Value int
+	var cfg struct {
+		Rules  []Rule      `json:"rules"`
+		Alerts []AlertRule `json:"alerts"`
 	}
-	var ss []kv
-	for k, v := range stats {
-		ss = append(ss, kv{k, v})
+	if err := json.Unmarshal(ruleData, &cfg); err == nil && (len(cfg.Rules) > 0 || len(cfg.Alerts) > 0) {
+		return cfg.Rules, cfg.Alerts
 	}
-	sort.Slice(ss, func(i, j int) bool {
-		return ss[i].Value > ss[j].Value
-	})
 
-	for i, kv := range ss {
-		if i >= n { break }
-		cleanKey := strings.ReplaceAll(kv.Key, "\"", "\\\"")
-		cleanKey = strings.ReplaceAll(cleanKey, "\n", "\\n")
-		fmt.Printf("{\n  \"%s\": \"%s\",\n  \"Count\": %d\n}\n", keyName, cleanKey, kv.Value)
-	}
-}
-
-func printRawErrorJSON(stats map[string]int, n int) {
-	type kv struct {
-		Key   string
-		Value int
-	}
-	var ss []kv
-	for k, v := range stats {
-		ss = append(ss, kv{k, v})
-	}
-	sort.Slice(ss, func(i, j int) bool {
-		return ss[i].Value > ss[j].Value
-	})
-
-	for i, kv := range ss {
-		if i >= n { break }
-		jsonKey := fmt.Sprintf("%q", kv.Key)
-		fmt.Printf("{\n  \"Errors\": %s,\n  \"Count\": %d\n}\n", jsonKey, kv.Value)
-	}
+	// Back-compat: rules.json used to be a flat array of advice Rules.
+	var flat []Rule
+	json.Unmarshal(ruleData, &flat)
+	return flat, nil
 }
 
 func mapToSortedSlice(m map[string]bool) []string {
 	s := make([]string, 0, len(m))
-	for k := range m { s = append(s, k) }
+	for k := range m {
+		s = append(s, k)
+	}
 	sort.Strings(s)
 	return s
-}
\ No newline at end of file
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"vault-analyzer-audit/internal/metrics"
+)
+
+// startMetricsServer registers reg with a fresh Prometheus registry and
+// serves it at /metrics on addr. ListenAndServe runs in its own goroutine;
+// a failure there is logged rather than fatal, since the text report and
+// follow loop should keep working even if the metrics listener dies.
+func startMetricsServer(addr string, reg *metrics.Registry) {
+	promReg := prometheus.NewRegistry()
+	promReg.MustRegister(reg)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(promReg, promhttp.HandlerOpts{}))
+
+	fmt.Printf("Serving Prometheus metrics on %s/metrics\n", addr)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Fprintf(os.Stderr, "metrics: server on %s exited: %v\n", addr, err)
+		}
+	}()
+}
+
+// toMetricsView adapts the internal Analysis accumulator to the decoupled
+// view type internal/metrics consumes, so that package doesn't need to
+// import package main.
+func toMetricsView(an *Analysis) metrics.AnalysisView {
+	view := metrics.AnalysisView{
+		PathStats:     an.PathStats,
+		RawErrorStats: an.RawErrorStats,
+	}
+	for _, s := range an.Stats {
+		view.Stats = append(view.Stats, metrics.StatView{
+			Path:      s.Path,
+			ErrorMsg:  s.ErrorMsg,
+			Namespace: s.Namespace,
+			Count:     s.Count,
+			FirstTime: s.FirstTime,
+			LastTime:  s.LastTime,
+			UniqueIPs: len(s.UniqueIPs),
+		})
+	}
+	return view
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reporter renders the incident-by-incident report and executive summary
+// for an accumulated Analysis in a specific output format. WriteIncident
+// is called once per incident in descending-count order, then WriteSummary
+// once; Close flushes anything the format still holds (the CSV writer).
+// The json, yaml and sarif formats write their single buffered document
+// from WriteSummary.
+type Reporter interface {
+	WriteIncident(inc *Stats, category, advice string, spikes []Spike) error
+	WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error
+	Close() error
+}
+
+// newReporter builds the Reporter for the given -format value.
+func newReporter(format string, w io.Writer) (Reporter, error) {
+	switch format {
+	case "", "text":
+		return newTextReporter(w), nil
+	case "json":
+		return newJSONReporter(w), nil
+	case "ndjson":
+		return newNDJSONReporter(w), nil
+	case "yaml":
+		return newYAMLReporter(w), nil
+	case "csv":
+		return newCSVReporter(w), nil
+	case "sarif":
+		return newSARIFReporter(w), nil
+	default:
+		return nil, fmt.Errorf("unknown -format %q (want text, json, ndjson, yaml, csv, or sarif)", format)
+	}
+}
+
+// writeReport sorts an's incidents by count (worst first, as the original
+// text report always did) and feeds them through rep, followed by the
+// executive summary. If anomalies is non-nil, each incident's detected
+// spikes (if any) and the overall spike count are included too.
+func writeReport(an *Analysis, rules []Rule, rep Reporter, anomalies *AnomalyDetector) error {
+	for _, inc := range sortedStats(an) {
+		category := categoryFor(inc.Path)
+		advice := adviceFor(inc, rules)
+		var spikes []Spike
+		if anomalies != nil {
+			spikes = anomalies.Spikes(inc.Signature)
+		}
+		if err := rep.WriteIncident(inc, category, advice, spikes); err != nil {
+			return fmt.Errorf("writing incident: %w", err)
+		}
+	}
+	anomalyCount := 0
+	if anomalies != nil {
+		anomalyCount = anomalies.TotalSpikes()
+	}
+	if err := rep.WriteSummary(an.PathStats, an.RawErrorStats, anomalyCount); err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+	return rep.Close()
+}
+
+func sortedStats(an *Analysis) []*Stats {
+	var sorted []*Stats
+	for _, s := range an.Stats {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Count > sorted[j].Count
+	})
+	return sorted
+}
+
+func categoryFor(path string) string {
+	switch {
+	case strings.HasPrefix(path, "sys/"):
+		return "SYS"
+	case strings.HasPrefix(path, "auth/"):
+		return "AUTH"
+	default:
+		return "DATA"
+	}
+}
+
+func adviceFor(inc *Stats, rules []Rule) string {
+	flatErr := strings.ReplaceAll(inc.ErrorMsg, "\n", " ")
+	fullSig := fmt.Sprintf("%s %s", inc.Path, flatErr)
+	for _, r := range rules {
+		if strings.Contains(fullSig, r.Pattern) {
+			return r.Advice
+		}
+	}
+	return "Investigate this error pattern."
+}
+
+// statCount is the shape every "top N" summary table (paths, error types)
+// renders to, across every structured format.
+type statCount struct {
+	Key   string `json:"key" yaml:"key"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+func topN(stats map[string]int, n int) []statCount {
+	var ss []statCount
+	for k, v := range stats {
+		ss = append(ss, statCount{Key: k, Count: v})
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Count > ss[j].Count
+	})
+	if len(ss) > n {
+		ss = ss[:n]
+	}
+	return ss
+}
+
+// jsonIncident is the structured-format projection of a Stats plus the
+// category/advice the report derives for it. It's shared by the json,
+// ndjson and yaml reporters.
+type jsonIncident struct {
+	Signature string    `json:"signature" yaml:"signature"`
+	Path      string    `json:"path" yaml:"path"`
+	ErrorMsg  string    `json:"error" yaml:"error"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Count     int       `json:"count" yaml:"count"`
+	FirstTime time.Time `json:"first_time" yaml:"first_time"`
+	LastTime  time.Time `json:"last_time" yaml:"last_time"`
+	UniqueIPs []string  `json:"unique_ips" yaml:"unique_ips"`
+	Category  string    `json:"category" yaml:"category"`
+	Advice    string    `json:"advice" yaml:"advice"`
+	Spikes    []Spike   `json:"spikes,omitempty" yaml:"spikes,omitempty"`
+}
+
+func toJSONIncident(inc *Stats, category, advice string, spikes []Spike) jsonIncident {
+	return jsonIncident{
+		Signature: inc.Signature,
+		Path:      inc.Path,
+		ErrorMsg:  inc.ErrorMsg,
+		Namespace: inc.Namespace,
+		Count:     inc.Count,
+		FirstTime: inc.FirstTime,
+		LastTime:  inc.LastTime,
+		UniqueIPs: mapToSortedSlice(inc.UniqueIPs),
+		Category:  category,
+		Advice:    advice,
+		Spikes:    spikes,
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvReporter writes one row per incident. CSV has no natural place for
+// the executive summary's nested top-N tables, so WriteSummary appends
+// them as a second, differently-shaped table below a blank separator row
+// rather than forcing them into incident columns.
+type csvReporter struct {
+	cw *csv.Writer
+}
+
+func newCSVReporter(w io.Writer) *csvReporter {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"category", "path", "error", "namespace", "count", "first_time", "last_time", "unique_ips", "advice", "spikes"})
+	return &csvReporter{cw: cw}
+}
+
+func (c *csvReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	return c.cw.Write([]string{
+		category,
+		inc.Path,
+		cleanForDisplay(inc.ErrorMsg),
+		inc.Namespace,
+		strconv.Itoa(inc.Count),
+		inc.FirstTime.Format(time.RFC3339),
+		inc.LastTime.Format(time.RFC3339),
+		strconv.Itoa(len(inc.UniqueIPs)),
+		advice,
+		strconv.Itoa(len(spikes)),
+	})
+}
+
+func (c *csvReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	c.cw.Write([]string{})
+	c.cw.Write([]string{"section", "key", "count"})
+	for _, sc := range topN(pathStats, 3) {
+		c.cw.Write([]string{"top_path", sc.Key, strconv.Itoa(sc.Count)})
+	}
+	for _, sc := range topN(errorStats, 5) {
+		c.cw.Write([]string{"top_error", sc.Key, strconv.Itoa(sc.Count)})
+	}
+	c.cw.Write([]string{"anomalies", "total_spikes", strconv.Itoa(anomalyCount)})
+	return c.cw.Error()
+}
+
+func (c *csvReporter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonReporter buffers every incident and emits a single JSON document
+// ({"incidents": [...], "summary": {...}}) on WriteSummary, rather than
+// streaming - a single document is what most JSON consumers expect,
+// unlike the ndjson format below.
+type jsonReporter struct {
+	w         io.Writer
+	incidents []jsonIncident
+}
+
+func newJSONReporter(w io.Writer) *jsonReporter {
+	return &jsonReporter{w: w}
+}
+
+func (j *jsonReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	j.incidents = append(j.incidents, toJSONIncident(inc, category, advice, spikes))
+	return nil
+}
+
+type jsonReportDoc struct {
+	Incidents []jsonIncident `json:"incidents"`
+	Summary   jsonSummary    `json:"summary"`
+}
+
+type jsonSummary struct {
+	TopPaths  []statCount `json:"top_paths" yaml:"top_paths"`
+	TopErrors []statCount `json:"top_errors" yaml:"top_errors"`
+	Anomalies int         `json:"anomalies" yaml:"anomalies"`
+}
+
+func (j *jsonReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	doc := jsonReportDoc{
+		Incidents: j.incidents,
+		Summary: jsonSummary{
+			TopPaths:  topN(pathStats, 3),
+			TopErrors: topN(errorStats, 5),
+			Anomalies: anomalyCount,
+		},
+	}
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func (j *jsonReporter) Close() error { return nil }
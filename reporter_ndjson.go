@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ndjsonReporter emits one JSON object per line, so an operator can pipe
+// the report straight into `jq` or a log shipper without buffering the
+// whole report first.
+type ndjsonReporter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONReporter(w io.Writer) *ndjsonReporter {
+	return &ndjsonReporter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	return n.enc.Encode(toJSONIncident(inc, category, advice, spikes))
+}
+
+type ndjsonSummaryLine struct {
+	Type      string      `json:"type"`
+	TopPaths  []statCount `json:"top_paths"`
+	TopErrors []statCount `json:"top_errors"`
+	Anomalies int         `json:"anomalies"`
+}
+
+func (n *ndjsonReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	return n.enc.Encode(ndjsonSummaryLine{
+		Type:      "summary",
+		TopPaths:  topN(pathStats, 3),
+		TopErrors: topN(errorStats, 5),
+		Anomalies: anomalyCount,
+	})
+}
+
+func (n *ndjsonReporter) Close() error { return nil }
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// sarifReporter renders incidents as a SARIF 2.1.0 log so the tool's
+// findings can be uploaded to GitHub code-scanning or any other SARIF
+// consumer alongside other security findings.
+type sarifReporter struct {
+	w       io.Writer
+	results []sarifResult
+}
+
+func newSARIFReporter(w io.Writer) *sarifReporter {
+	return &sarifReporter{w: w}
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID     string         `json:"ruleId"`
+	Level      string         `json:"level"`
+	Message    sarifMessage   `json:"message"`
+	Locations  []sarifLoc     `json:"locations"`
+	Properties map[string]int `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLoc struct {
+	PhysicalLocation sarifPhysicalLoc `json:"physicalLocation"`
+}
+
+type sarifPhysicalLoc struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+}
+
+type sarifArtifactLoc struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps an incident's severity onto SARIF's level enum. There's
+// no hard Vault-defined threshold for "this is an error vs. a warning", so
+// this uses the same rough volume heuristic operators already use when
+// triaging the text report by eye.
+func sarifLevel(count int) string {
+	if count > 100 {
+		return "error"
+	}
+	return "warning"
+}
+
+func (s *sarifReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	level := sarifLevel(inc.Count)
+	if len(spikes) > 0 {
+		level = "error" // a detected spike outranks the plain volume heuristic
+	}
+	s.results = append(s.results, sarifResult{
+		RuleID: category,
+		Level:  level,
+		Message: sarifMessage{
+			Text: fmt.Sprintf("%s (%d occurrences, %d spikes): %s", cleanForDisplay(inc.ErrorMsg), inc.Count, len(spikes), advice),
+		},
+		Locations: []sarifLoc{{
+			PhysicalLocation: sarifPhysicalLoc{
+				ArtifactLocation: sarifArtifactLoc{URI: inc.Path},
+			},
+		}},
+		Properties: map[string]int{
+			"count":     inc.Count,
+			"uniqueIPs": len(inc.UniqueIPs),
+			"spikes":    len(spikes),
+		},
+	})
+	return nil
+}
+
+// WriteSummary has nothing SARIF-shaped to add; the executive summary's
+// top-N tables aren't findings, they're aggregate context, so they're
+// intentionally dropped from the SARIF output rather than stuffed into a
+// non-standard extension field.
+func (s *sarifReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	doc := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:    "vault-analyzer-audit",
+				Version: "dev",
+			}},
+			Results: s.results,
+		}},
+	}
+	enc := json.NewEncoder(s.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+func (s *sarifReporter) Close() error { return nil }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// textReporter reproduces the original human-readable report layout,
+// adding a SPIKES line to incidents with detected spikes and an ANOMALIES
+// section after the summary; it's still the default format.
+type textReporter struct {
+	w io.Writer
+}
+
+func newTextReporter(w io.Writer) *textReporter {
+	fmt.Fprintln(w, "VAULT AUDIT ANALYSIS REPORT")
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	return &textReporter{w: w}
+}
+
+func (t *textReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	duration := inc.LastTime.Sub(inc.FirstTime)
+
+	fmt.Fprintf(t.w, "%-12s [%s]\n", "CATEGORY:", category)
+	fmt.Fprintf(t.w, "%-12s %d\n", "COUNT:", inc.Count)
+	fmt.Fprintf(t.w, "%-12s %s\n", "PATH:", inc.Path)
+	fmt.Fprintf(t.w, "%-12s %s\n", "ERROR:", cleanForDisplay(inc.ErrorMsg))
+
+	fmt.Fprintf(t.w, "%-12s %s -> %s (%s)\n",
+		"TIMEFRAME:",
+		inc.FirstTime.Format("15:04:05"),
+		inc.LastTime.Format("15:04:05"),
+		duration)
+
+	fmt.Fprintf(t.w, "%-12s %v\n", "SOURCES:", mapToSortedSlice(inc.UniqueIPs))
+	fmt.Fprintf(t.w, "%-12s %s\n", "ANALYSIS:", advice)
+	if len(spikes) > 0 {
+		fmt.Fprintf(t.w, "%-12s %s\n", "SPIKES:", formatSpikes(spikes))
+	}
+	fmt.Fprintln(t.w, strings.Repeat("-", 80))
+	return nil
+}
+
+func formatSpikes(spikes []Spike) string {
+	parts := make([]string, len(spikes))
+	for i, s := range spikes {
+		parts[i] = fmt.Sprintf("%s (observed %d vs expected %.1f±%.1f)",
+			s.Bucket.Format("15:04:05"), s.Observed, s.Expected, s.StdDev)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (t *textReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	fmt.Fprintln(t.w, "\nEXECUTIVE SUMMARY")
+	fmt.Fprintln(t.w, strings.Repeat("=", 80))
+
+	fmt.Fprintln(t.w, "TOP FAILING PATHS (JSON):")
+	writeJSONStats(t.w, pathStats, "Path", 3)
+	fmt.Fprintln(t.w, "")
+
+	fmt.Fprintln(t.w, "TOP ERROR TYPES (JSON):")
+	writeRawErrorJSON(t.w, errorStats, 5)
+	fmt.Fprintln(t.w, strings.Repeat("=", 80))
+
+	fmt.Fprintln(t.w, "\nANOMALIES")
+	fmt.Fprintln(t.w, strings.Repeat("=", 80))
+	fmt.Fprintf(t.w, "Spikes detected: %d\n", anomalyCount)
+	fmt.Fprintln(t.w, strings.Repeat("=", 80))
+	return nil
+}
+
+func (t *textReporter) Close() error { return nil }
+
+func writeJSONStats(w io.Writer, stats map[string]int, keyName string, n int) {
+	type kv struct {
+		Key   string
+		Value int
+	}
+	var ss []kv
+	for k, v := range stats {
+		ss = append(ss, kv{k, v})
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Value > ss[j].Value
+	})
+
+	for i, kv := range ss {
+		if i >= n {
+			break
+		}
+		cleanKey := strings.ReplaceAll(kv.Key, "\"", "\\\"")
+		cleanKey = strings.ReplaceAll(cleanKey, "\n", "\\n")
+		fmt.Fprintf(w, "{\n  \"%s\": \"%s\",\n  \"Count\": %d\n}\n", keyName, cleanKey, kv.Value)
+	}
+}
+
+func writeRawErrorJSON(w io.Writer, stats map[string]int, n int) {
+	type kv struct {
+		Key   string
+		Value int
+	}
+	var ss []kv
+	for k, v := range stats {
+		ss = append(ss, kv{k, v})
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].Value > ss[j].Value
+	})
+
+	for i, kv := range ss {
+		if i >= n {
+			break
+		}
+		jsonKey := fmt.Sprintf("%q", kv.Key)
+		fmt.Fprintf(w, "{\n  \"Errors\": %s,\n  \"Count\": %d\n}\n", jsonKey, kv.Value)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlReporter mirrors jsonReporter: buffer every incident, emit one
+// document on WriteSummary.
+type yamlReporter struct {
+	w         io.Writer
+	incidents []jsonIncident
+}
+
+func newYAMLReporter(w io.Writer) *yamlReporter {
+	return &yamlReporter{w: w}
+}
+
+func (y *yamlReporter) WriteIncident(inc *Stats, category, advice string, spikes []Spike) error {
+	y.incidents = append(y.incidents, toJSONIncident(inc, category, advice, spikes))
+	return nil
+}
+
+type yamlReportDoc struct {
+	Incidents []jsonIncident `yaml:"incidents"`
+	Summary   jsonSummary    `yaml:"summary"`
+}
+
+func (y *yamlReporter) WriteSummary(pathStats, errorStats map[string]int, anomalyCount int) error {
+	doc := yamlReportDoc{
+		Incidents: y.incidents,
+		Summary: jsonSummary{
+			TopPaths:  topN(pathStats, 3),
+			TopErrors: topN(errorStats, 5),
+			Anomalies: anomalyCount,
+		},
+	}
+	enc := yaml.NewEncoder(y.w)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (y *yamlReporter) Close() error { return nil }